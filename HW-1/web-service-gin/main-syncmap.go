@@ -5,6 +5,10 @@ import (
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/justin-aj/go-hw1/logging"
+	"github.com/justin-aj/go-hw1/metrics"
 )
 
 // album represents data about a record album.
@@ -34,6 +38,8 @@ func init() {
 
 func main() {
 	router := gin.Default()
+	router.Use(logging.Middleware(), metrics.Middleware())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/albums", getAlbums)
 	router.POST("/albums", postAlbums)
 	router.GET("/albums/:id", getAlbumByID)
@@ -0,0 +1,23 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// directSupported reports whether this platform has an O_DIRECT variant of
+// fileWriteDirect to run. O_DIRECT itself is Linux-only (Darwin/BSD use
+// F_NOCACHE via fcntl instead), so this is the only build-tagged file pair
+// in the tree.
+const directSupported = true
+
+// openDirect opens filename for unbuffered writes, bypassing the page cache
+// the way buffered/unbuffered-but-still-cached writes don't. Writes must be
+// page-aligned and a multiple of directBlockSize, which is why
+// fileWriteDirect buffers into alignedBuffer rather than writing strings
+// straight through like fileWrite does.
+func openDirect(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|syscall.O_DIRECT, 0o644)
+}
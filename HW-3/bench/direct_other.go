@@ -0,0 +1,19 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// directSupported is false everywhere but Linux: O_DIRECT isn't a portable
+// flag, and this bench only needs the one code path to compare against
+// buffered/unbuffered writes.
+const directSupported = false
+
+// openDirect always fails on this platform; runSweep checks directSupported
+// first and logs that the variant was skipped rather than calling this.
+func openDirect(filename string) (*os.File, error) {
+	return nil, fmt.Errorf("O_DIRECT benchmark variant is not supported on this platform")
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Task states.
+const (
+	TaskIdle       = "idle"
+	TaskInProgress = "in-progress"
+	TaskDone       = "done"
+)
+
+// Task types.
+const (
+	TaskMap    = "map"
+	TaskReduce = "reduce"
+)
+
+const (
+	heartbeatWindow = 30 * time.Second
+	maxAttempts     = 5
+	baseBackoff     = 1 * time.Second
+	schedulerTick   = 2 * time.Second
+)
+
+// task is a single unit of work handed to a worker. Attempts counts
+// scheduler-level (re)dispatches, bumped once per dispatch call; it is
+// separate from the HTTP retry budget runTask spends against a single
+// worker within one dispatch.
+type task struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Bucket     string    `json:"bucket"`
+	Key        string    `json:"key"`        // map: input key. reduce: comma-separated mapper output keys.
+	OutputKey  string    `json:"output_key"`
+	Partition  int       `json:"partition"`
+	State      string    `json:"state"`
+	Worker     string    `json:"worker,omitempty"`
+	Attempts   int       `json:"attempts"`
+	AssignedAt time.Time `json:"assigned_at,omitempty"`
+
+	// cancel stops the in-flight runTask goroutine for this dispatch, so
+	// reapStaleTasks can make a reassignment final instead of racing the
+	// original goroutine's own retry loop. nil when the task isn't dispatched.
+	cancel context.CancelFunc
+}
+
+// job tracks all tasks for one submitted MapReduce run. Partitions and the
+// combiner settings are fixed at submission time and threaded through to
+// every map/reduce task so mappers and reducers agree on how output is
+// partitioned.
+type job struct {
+	ID             string    `json:"id"`
+	Bucket         string    `json:"bucket"`
+	InputPrefix    string    `json:"input_prefix"`
+	State          string    `json:"state"` // pending, mapping, reducing, done, failed
+	Partitions     int       `json:"partitions"`
+	TopK           string    `json:"topk,omitempty"`
+	StopWords      string    `json:"stopwords,omitempty"`
+	SpillThreshold string    `json:"spill_threshold,omitempty"`
+	MapTasks       []*task   `json:"map_tasks"`
+	ReduceTasks    []*task   `json:"reduce_tasks"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// worker is a registered map/reduce executor.
+type worker struct {
+	Addr        string    `json:"addr"`
+	Concurrency int       `json:"concurrency"`
+	ActiveTasks int       `json:"active_tasks"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// coordinator holds all in-memory scheduling state. A real deployment would
+// back this with something durable, but for this project in-memory is
+// sufficient and keeps the worker registration/dispatch loop simple.
+type coordinator struct {
+	mu         sync.Mutex
+	jobs       map[string]*job
+	workers    map[string]*worker
+	nextJobID  int
+	nextTaskID int
+	client     *http.Client
+}
+
+func newCoordinator() *coordinator {
+	return &coordinator{
+		jobs:    make(map[string]*job),
+		workers: make(map[string]*worker),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (co *coordinator) registerWorker(addr string, concurrency int) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	co.workers[addr] = &worker{Addr: addr, Concurrency: concurrency, LastSeen: time.Now()}
+}
+
+// leastLoadedWorker returns, among registered workers whose ActiveTasks is
+// below their Concurrency, the one with the fewest active tasks, or "" if
+// none has spare capacity.
+func (co *coordinator) leastLoadedWorker() string {
+	var best *worker
+	for _, w := range co.workers {
+		if w.ActiveTasks >= w.Concurrency {
+			continue
+		}
+		if best == nil || w.ActiveTasks < best.ActiveTasks {
+			best = w
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Addr
+}
+
+func (co *coordinator) newTaskID() string {
+	co.nextTaskID++
+	return fmt.Sprintf("task-%d", co.nextTaskID)
+}
+
+// submitJob creates one map task per input key (the caller is expected to
+// have already chunked large inputs, e.g. one object per key) and records
+// the partition count; advanceJob creates one reduce task per partition once
+// all map tasks for the job are done.
+func (co *coordinator) submitJob(bucket, prefix string, inputKeys []string, partitions int, topK, stopWords, spillThreshold string) *job {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	if partitions <= 0 {
+		partitions = 1
+	}
+
+	co.nextJobID++
+	j := &job{
+		ID:             fmt.Sprintf("job-%d", co.nextJobID),
+		Bucket:         bucket,
+		InputPrefix:    prefix,
+		State:          "mapping",
+		Partitions:     partitions,
+		TopK:           topK,
+		StopWords:      stopWords,
+		SpillThreshold: spillThreshold,
+		CreatedAt:      time.Now(),
+	}
+
+	for _, key := range inputKeys {
+		j.MapTasks = append(j.MapTasks, &task{
+			ID:        co.newTaskID(),
+			Type:      TaskMap,
+			Bucket:    bucket,
+			Key:       key,
+			OutputKey: fmt.Sprintf("intermediate/%s/%s.json", j.ID, key),
+			State:     TaskIdle,
+		})
+	}
+
+	co.jobs[j.ID] = j
+	return j
+}
+
+// scheduleOnce advances every in-flight job by one tick: it reassigns tasks
+// that have stalled past the heartbeat window and dispatches idle tasks to
+// whichever registered worker has spare capacity.
+func (co *coordinator) scheduleOnce() {
+	co.mu.Lock()
+	jobs := make([]*job, 0, len(co.jobs))
+	for _, j := range co.jobs {
+		jobs = append(jobs, j)
+	}
+	co.mu.Unlock()
+
+	for _, j := range jobs {
+		co.reapStaleTasks(j)
+		co.advanceJob(j)
+	}
+}
+
+func (co *coordinator) reapStaleTasks(j *job) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	for _, t := range append(append([]*task{}, j.MapTasks...), j.ReduceTasks...) {
+		if t.State != TaskInProgress {
+			continue
+		}
+		if time.Since(t.AssignedAt) < heartbeatWindow {
+			continue
+		}
+		if t.Attempts >= maxAttempts {
+			j.State = "failed"
+			continue
+		}
+		log.Printf("coordinator: task %s on worker %s missed heartbeat, reassigning", t.ID, t.Worker)
+		if t.cancel != nil {
+			t.cancel()
+			t.cancel = nil
+		}
+		if w, ok := co.workers[t.Worker]; ok && w.ActiveTasks > 0 {
+			w.ActiveTasks--
+		}
+		t.State = TaskIdle
+		t.Worker = ""
+	}
+}
+
+func (co *coordinator) advanceJob(j *job) {
+	co.mu.Lock()
+	if j.State == "failed" || j.State == "done" {
+		co.mu.Unlock()
+		return
+	}
+
+	switch j.State {
+	case "mapping":
+		for _, t := range j.MapTasks {
+			co.dispatch(t, j)
+		}
+		if allDone(j.MapTasks) {
+			mapperKeys := mapperOutputKeys(j.MapTasks)
+			j.ReduceTasks = make([]*task, 0, j.Partitions)
+			for p := 0; p < j.Partitions; p++ {
+				j.ReduceTasks = append(j.ReduceTasks, &task{
+					ID:        co.newTaskID(),
+					Type:      TaskReduce,
+					Bucket:    j.Bucket,
+					Key:       mapperKeys,
+					OutputKey: fmt.Sprintf("results/%s/final_counts.part-%03d.json", j.ID, p),
+					Partition: p,
+					State:     TaskIdle,
+				})
+			}
+			j.State = "reducing"
+		}
+	case "reducing":
+		for _, t := range j.ReduceTasks {
+			co.dispatch(t, j)
+		}
+		if allDone(j.ReduceTasks) {
+			j.State = "done"
+		}
+	}
+	co.mu.Unlock()
+}
+
+func mapperOutputKeys(tasks []*task) string {
+	keys := ""
+	for i, t := range tasks {
+		if i > 0 {
+			keys += ","
+		}
+		keys += t.OutputKey
+	}
+	return keys
+}
+
+func allDone(tasks []*task) bool {
+	for _, t := range tasks {
+		if t.State != TaskDone {
+			return false
+		}
+	}
+	return len(tasks) > 0
+}
+
+// dispatch assigns an idle task to the least-loaded worker and fires the
+// HTTP call in the background with exponential backoff retries. Must be
+// called with co.mu held.
+func (co *coordinator) dispatch(t *task, j *job) {
+	if t.State != TaskIdle {
+		return
+	}
+	addr := co.leastLoadedWorker()
+	if addr == "" {
+		return
+	}
+
+	w := co.workers[addr]
+	w.ActiveTasks++
+	t.State = TaskInProgress
+	t.Worker = addr
+	t.AssignedAt = time.Now()
+	t.Attempts++
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	go co.runTask(ctx, t, addr, j)
+}
+
+// runTask fires the HTTP call for t against addr, threading the job's
+// partition count and combiner settings through as query params so every
+// mapper/reducer in the job agrees on how output is partitioned. It retries
+// against addr with backoff up to maxAttempts, independent of the task's own
+// scheduler-level Attempts counter, but bails the moment ctx is cancelled
+// (reapStaleTasks has already reassigned the task) instead of touching
+// shared task/worker state that a newer dispatch now owns.
+func (co *coordinator) runTask(ctx context.Context, t *task, addr string, j *job) {
+	url := fmt.Sprintf("%s/map?task_id=%s&bucket=%s&key=%s&output_key=%s&partitions=%d",
+		addr, t.ID, t.Bucket, t.Key, t.OutputKey, j.Partitions)
+	if j.TopK != "" {
+		url += "&topk=" + j.TopK
+	}
+	if j.StopWords != "" {
+		url += "&stopwords=" + j.StopWords
+	}
+	if j.SpillThreshold != "" {
+		url += "&spill_threshold=" + j.SpillThreshold
+	}
+	if t.Type == TaskReduce {
+		url = fmt.Sprintf("%s/reduce?task_id=%s&bucket=%s&keys=%s&partition=%d", addr, t.ID, t.Bucket, t.Key, t.Partition)
+	}
+
+	backoff := baseBackoff
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if ctx.Err() != nil {
+			return
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err == nil {
+			var resp *http.Response
+			resp, err = co.client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode == http.StatusOK {
+					co.markDone(t)
+					return
+				}
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(backoff + jitter):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Ran out of retries against this worker; leave it idle so the next
+	// scheduler tick picks a different one.
+	co.mu.Lock()
+	if w, ok := co.workers[addr]; ok && w.ActiveTasks > 0 {
+		w.ActiveTasks--
+	}
+	t.State = TaskIdle
+	t.Worker = ""
+	t.cancel = nil
+	co.mu.Unlock()
+}
+
+func (co *coordinator) markDone(t *task) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	t.State = TaskDone
+	t.cancel = nil
+	if w, ok := co.workers[t.Worker]; ok && w.ActiveTasks > 0 {
+		w.ActiveTasks--
+	}
+}
+
+func (co *coordinator) runScheduler() {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+	for range ticker.C {
+		co.scheduleOnce()
+	}
+}
+
+func main() {
+	co := newCoordinator()
+	go co.runScheduler()
+
+	r := gin.Default()
+
+	r.POST("/register", func(c *gin.Context) {
+		var req struct {
+			Addr        string `json:"addr" binding:"required"`
+			Concurrency int    `json:"concurrency"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return
+		}
+		if req.Concurrency <= 0 {
+			req.Concurrency = 1
+		}
+		co.registerWorker(req.Addr, req.Concurrency)
+		c.JSON(http.StatusOK, gin.H{"message": "registered", "addr": req.Addr})
+	})
+
+	r.POST("/job", func(c *gin.Context) {
+		var req struct {
+			Bucket         string   `json:"bucket" binding:"required"`
+			Prefix         string   `json:"prefix"`
+			Keys           []string `json:"keys" binding:"required"`
+			Partitions     int      `json:"partitions"`
+			TopK           string   `json:"topk"`
+			StopWords      string   `json:"stopwords"`
+			SpillThreshold string   `json:"spill_threshold"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return
+		}
+		j := co.submitJob(req.Bucket, req.Prefix, req.Keys, req.Partitions, req.TopK, req.StopWords, req.SpillThreshold)
+		c.JSON(http.StatusOK, gin.H{"job_id": j.ID, "state": j.State})
+	})
+
+	r.GET("/status/:job_id", func(c *gin.Context) {
+		co.mu.Lock()
+		j, ok := co.jobs[c.Param("job_id")]
+		co.mu.Unlock()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		c.JSON(http.StatusOK, j)
+	})
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+
+	r.Run(":8090")
+}
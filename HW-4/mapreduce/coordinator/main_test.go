@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLeastLoadedWorker(t *testing.T) {
+	co := newCoordinator()
+	co.registerWorker("worker-a", 2)
+	co.registerWorker("worker-b", 2)
+
+	co.workers["worker-a"].ActiveTasks = 1
+
+	if got := co.leastLoadedWorker(); got != "worker-b" {
+		t.Fatalf("leastLoadedWorker() = %q, want worker-b", got)
+	}
+
+	co.workers["worker-a"].ActiveTasks = 2
+	co.workers["worker-b"].ActiveTasks = 2
+	if got := co.leastLoadedWorker(); got != "" {
+		t.Fatalf("leastLoadedWorker() = %q, want \"\" when all workers are saturated", got)
+	}
+}
+
+func TestReapStaleTasksReassignsAfterHeartbeatWindow(t *testing.T) {
+	co := newCoordinator()
+	co.registerWorker("worker-a", 2)
+	co.workers["worker-a"].ActiveTasks = 1
+
+	tk := &task{
+		ID:         "task-1",
+		Type:       TaskMap,
+		State:      TaskInProgress,
+		Worker:     "worker-a",
+		AssignedAt: time.Now().Add(-2 * heartbeatWindow),
+		Attempts:   1,
+	}
+	j := &job{ID: "job-1", MapTasks: []*task{tk}}
+
+	co.reapStaleTasks(j)
+
+	if tk.State != TaskIdle || tk.Worker != "" {
+		t.Fatalf("expected stale task to be reset to idle, got state=%s worker=%s", tk.State, tk.Worker)
+	}
+	if co.workers["worker-a"].ActiveTasks != 0 {
+		t.Fatalf("expected worker ActiveTasks to be decremented, got %d", co.workers["worker-a"].ActiveTasks)
+	}
+}
+
+func TestReapStaleTasksFailsJobAfterMaxAttempts(t *testing.T) {
+	co := newCoordinator()
+	tk := &task{
+		ID:         "task-1",
+		Type:       TaskMap,
+		State:      TaskInProgress,
+		AssignedAt: time.Now().Add(-2 * heartbeatWindow),
+		Attempts:   maxAttempts,
+	}
+	j := &job{ID: "job-1", MapTasks: []*task{tk}, State: "mapping"}
+
+	co.reapStaleTasks(j)
+
+	if j.State != "failed" {
+		t.Fatalf("expected job to be marked failed after exhausting attempts, got %q", j.State)
+	}
+}
+
+func TestAdvanceJobFansOutOneReduceTaskPerPartition(t *testing.T) {
+	co := newCoordinator()
+	j := co.submitJob("bucket", "prefix", []string{"chunk-0", "chunk-1"}, 3, "", "", "")
+	for _, mt := range j.MapTasks {
+		mt.State = TaskDone
+	}
+
+	co.advanceJob(j)
+
+	if j.State != "reducing" {
+		t.Fatalf("job.State = %q, want reducing", j.State)
+	}
+	if len(j.ReduceTasks) != 3 {
+		t.Fatalf("len(j.ReduceTasks) = %d, want 3 (one per partition)", len(j.ReduceTasks))
+	}
+	seen := make(map[int]bool)
+	outputKeys := make(map[string]bool)
+	for _, rt := range j.ReduceTasks {
+		seen[rt.Partition] = true
+		if outputKeys[rt.OutputKey] {
+			t.Fatalf("duplicate reduce output key %q across partitions", rt.OutputKey)
+		}
+		outputKeys[rt.OutputKey] = true
+	}
+	for p := 0; p < 3; p++ {
+		if !seen[p] {
+			t.Fatalf("missing reduce task for partition %d", p)
+		}
+	}
+}
+
+func TestRunTaskBailsImmediatelyWhenReaped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	co := newCoordinator()
+	co.registerWorker(srv.URL, 1)
+	co.workers[srv.URL].ActiveTasks = 1
+
+	tk := &task{ID: "task-1", Type: TaskMap, State: TaskInProgress, Worker: srv.URL, AssignedAt: time.Now()}
+	j := &job{ID: "job-1", Partitions: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tk.cancel = cancel
+
+	done := make(chan struct{})
+	go func() {
+		co.runTask(ctx, tk, srv.URL, j)
+		close(done)
+	}()
+
+	// Simulate reapStaleTasks reassigning the task out from under the
+	// in-flight runTask goroutine, as it would on a missed heartbeat.
+	co.mu.Lock()
+	tk.cancel()
+	tk.State = TaskIdle
+	tk.Worker = ""
+	co.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runTask did not return after its context was cancelled")
+	}
+
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	if tk.State != TaskIdle {
+		t.Fatalf("task.State = %q, want idle (runTask must not overwrite a reassignment)", tk.State)
+	}
+	if co.workers[srv.URL].ActiveTasks != 1 {
+		t.Fatalf("ActiveTasks = %d, want 1 (runTask must not double-decrement after being reaped)", co.workers[srv.URL].ActiveTasks)
+	}
+}
+
+func TestAllDone(t *testing.T) {
+	if allDone(nil) {
+		t.Fatal("allDone(nil) = true, want false")
+	}
+	tasks := []*task{{State: TaskDone}, {State: TaskInProgress}}
+	if allDone(tasks) {
+		t.Fatal("allDone() = true with an in-progress task, want false")
+	}
+	tasks[1].State = TaskDone
+	if !allDone(tasks) {
+		t.Fatal("allDone() = false with all tasks done, want true")
+	}
+}
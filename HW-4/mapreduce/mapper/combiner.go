@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultStopWords are filtered out of every combiner run regardless of the
+// caller-supplied list; they're common enough that counting them is rarely
+// useful and they would otherwise dominate the output.
+var defaultStopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "and": true, "or": true,
+	"of": true, "to": true, "in": true, "is": true, "it": true,
+}
+
+// combinerConfig controls the mapper's pre-aggregation before upload.
+type combinerConfig struct {
+	topK           int
+	stopWords      map[string]bool
+	spillThreshold int
+	partitions     int
+}
+
+func parseCombinerConfig(topKParam, stopWordsParam, spillParam, partitionsParam string) combinerConfig {
+	cfg := combinerConfig{
+		spillThreshold: 5000,
+		partitions:     1,
+		stopWords:      defaultStopWords,
+	}
+
+	if v, err := strconv.Atoi(topKParam); err == nil && v > 0 {
+		cfg.topK = v
+	}
+	if v, err := strconv.Atoi(spillParam); err == nil && v > 0 {
+		cfg.spillThreshold = v
+	}
+	if v, err := strconv.Atoi(partitionsParam); err == nil && v > 0 {
+		cfg.partitions = v
+	}
+	if stopWordsParam != "" {
+		cfg.stopWords = make(map[string]bool, len(defaultStopWords))
+		for w, ok := range defaultStopWords {
+			cfg.stopWords[w] = ok
+		}
+		for _, w := range strings.Split(stopWordsParam, ",") {
+			cfg.stopWords[strings.ToLower(strings.TrimSpace(w))] = true
+		}
+	}
+
+	return cfg
+}
+
+// wordCount is one combined (word, count) pair.
+type wordCount struct {
+	word  string
+	count int
+}
+
+// combine tokenizes words, filters stop words, and spills sorted runs to
+// temp files once the in-memory map grows past the spill threshold. It
+// returns the merged, sorted (and optionally top-K filtered) word counts.
+func combine(words []string, cfg combinerConfig) ([]wordCount, error) {
+	var runFiles []string
+	defer func() {
+		for _, f := range runFiles {
+			os.Remove(f)
+		}
+	}()
+
+	current := make(map[string]int)
+	flush := func() error {
+		if len(current) == 0 {
+			return nil
+		}
+		path, err := spillRun(current)
+		if err != nil {
+			return err
+		}
+		runFiles = append(runFiles, path)
+		current = make(map[string]int)
+		return nil
+	}
+
+	for _, word := range words {
+		cleaned := strings.ToLower(strings.Trim(word, ".,!?;:\"'()[]{}"))
+		if cleaned == "" || cfg.stopWords[cleaned] {
+			continue
+		}
+		current[cleaned]++
+		if len(current) > cfg.spillThreshold {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeRuns(runFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.topK > 0 && len(merged) > cfg.topK {
+		sort.Slice(merged, func(i, j int) bool { return merged[i].count > merged[j].count })
+		merged = merged[:cfg.topK]
+		sort.Slice(merged, func(i, j int) bool { return merged[i].word < merged[j].word })
+	}
+
+	return merged, nil
+}
+
+// spillRun writes m to a temp file as sorted "word\tcount" lines.
+func spillRun(m map[string]int) (string, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f, err := os.CreateTemp("", "mapper-run-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", k, m[k]); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// runCursor is one spilled run's current (unread) line, used as a heap item
+// during the k-way merge.
+type runCursor struct {
+	scanner *bufio.Scanner
+	file    *os.File
+	word    string
+	count   int
+}
+
+func (rc *runCursor) advance() bool {
+	if !rc.scanner.Scan() {
+		return false
+	}
+	word, count := parseLine(rc.scanner.Text())
+	rc.word, rc.count = word, count
+	return true
+}
+
+func parseLine(line string) (string, int) {
+	idx := strings.LastIndexByte(line, '\t')
+	if idx < 0 {
+		return line, 0
+	}
+	count, _ := strconv.Atoi(line[idx+1:])
+	return line[:idx], count
+}
+
+type cursorHeap []*runCursor
+
+func (h cursorHeap) Len() int            { return len(h) }
+func (h cursorHeap) Less(i, j int) bool  { return h[i].word < h[j].word }
+func (h cursorHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeap) Push(x interface{}) { *h = append(*h, x.(*runCursor)) }
+func (h *cursorHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// mergeRuns k-way merges the sorted spill files, summing counts for keys
+// that landed in more than one run. The heap holds one line per run; the
+// returned slice holds every distinct word.
+func mergeRuns(runFiles []string) ([]wordCount, error) {
+	if len(runFiles) == 0 {
+		return nil, nil
+	}
+
+	h := &cursorHeap{}
+	for _, path := range runFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		rc := &runCursor{scanner: bufio.NewScanner(f), file: f}
+		if rc.advance() {
+			heap.Push(h, rc)
+		} else {
+			f.Close()
+		}
+	}
+	defer func() {
+		for _, rc := range *h {
+			rc.file.Close()
+		}
+	}()
+
+	var merged []wordCount
+	for h.Len() > 0 {
+		top := (*h)[0]
+		word, count := top.word, top.count
+
+		// Drain every cursor currently pointing at the same word.
+		for h.Len() > 0 && (*h)[0].word == word {
+			rc := heap.Pop(h).(*runCursor)
+			if rc != top {
+				count += rc.count
+			}
+			if rc.advance() {
+				heap.Push(h, rc)
+			} else {
+				rc.file.Close()
+			}
+		}
+
+		merged = append(merged, wordCount{word: word, count: count})
+	}
+
+	return merged, nil
+}
+
+// partitionOutputKey returns the object key for partition p of outputKey.
+func partitionOutputKey(outputKey string, p int) string {
+	return fmt.Sprintf("%s.part-%03d", outputKey, p)
+}
+
+// partitionOf hashes a word into [0, partitions).
+func partitionOf(word string, partitions int) int {
+	if partitions <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(word))
+	return int(h.Sum32() % uint32(partitions))
+}
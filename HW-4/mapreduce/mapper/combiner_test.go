@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func wordCounts(words []string, cfg combinerConfig) map[string]int {
+	result, err := combine(words, cfg)
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]int, len(result))
+	for _, wc := range result {
+		out[wc.word] = wc.count
+	}
+	return out
+}
+
+func TestCombineCountsAndFiltersStopWords(t *testing.T) {
+	cfg := parseCombinerConfig("", "", "", "")
+	got := wordCounts([]string{"the", "Cat", "sat", "on", "the", "cat."}, cfg)
+
+	want := map[string]int{"cat": 2, "sat": 1, "on": 1}
+	for w, c := range want {
+		if got[w] != c {
+			t.Errorf("count[%q] = %d, want %d", w, got[w], c)
+		}
+	}
+	if _, ok := got["the"]; ok {
+		t.Errorf("expected default stop word %q to be filtered", "the")
+	}
+}
+
+func TestCombineSpillsAndMergesAcrossRuns(t *testing.T) {
+	cfg := parseCombinerConfig("", "", "2", "") // spill after 2 distinct words per run
+	words := []string{"alpha", "beta", "gamma", "alpha", "beta", "alpha"}
+
+	got := wordCounts(words, cfg)
+	want := map[string]int{"alpha": 3, "beta": 2, "gamma": 1}
+	if len(got) != len(want) {
+		t.Fatalf("got %d distinct words, want %d: %v", len(got), len(want), got)
+	}
+	for w, c := range want {
+		if got[w] != c {
+			t.Errorf("count[%q] = %d, want %d (spilled runs must merge correctly)", w, got[w], c)
+		}
+	}
+}
+
+func TestCombineTopK(t *testing.T) {
+	cfg := parseCombinerConfig("2", "", "", "")
+	words := []string{"rare", "common", "common", "common", "medium", "medium"}
+
+	result, err := combine(words, cfg)
+	if err != nil {
+		t.Fatalf("combine() error = %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("len(result) = %d, want 2 (topK)", len(result))
+	}
+	for _, wc := range result {
+		if wc.word == "rare" {
+			t.Errorf("expected least-frequent word %q to be dropped by topK", "rare")
+		}
+	}
+}
+
+func TestCombineCustomStopWords(t *testing.T) {
+	cfg := parseCombinerConfig("", "banana", "", "")
+	got := wordCounts([]string{"banana", "apple", "banana"}, cfg)
+
+	if _, ok := got["banana"]; ok {
+		t.Errorf("expected custom stop word %q to be filtered", "banana")
+	}
+	if got["apple"] != 1 {
+		t.Errorf("count[apple] = %d, want 1", got["apple"])
+	}
+	// Default stop words must still apply alongside the custom list.
+	gotWithDefault := wordCounts([]string{"the", "banana"}, cfg)
+	if _, ok := gotWithDefault["the"]; ok {
+		t.Errorf("expected default stop word %q to still be filtered alongside custom list", "the")
+	}
+}
+
+func TestPartitionOfIsStableAndInRange(t *testing.T) {
+	const partitions = 4
+	first := partitionOf("hello", partitions)
+	second := partitionOf("hello", partitions)
+	if first != second {
+		t.Fatalf("partitionOf is not stable: %d != %d", first, second)
+	}
+	if first < 0 || first >= partitions {
+		t.Fatalf("partitionOf(%q, %d) = %d, out of range", "hello", partitions, first)
+	}
+}
+
+func TestPartitionOfSinglePartitionAlwaysZero(t *testing.T) {
+	if p := partitionOf("anything", 1); p != 0 {
+		t.Fatalf("partitionOf with 1 partition = %d, want 0", p)
+	}
+	if p := partitionOf("anything", 0); p != 0 {
+		t.Fatalf("partitionOf with 0 partitions = %d, want 0", p)
+	}
+}
@@ -1,103 +1,163 @@
 package main
 
 import (
-	"context"
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/justin-aj/go-hw1/HW-4/mapreduce/storage"
+	"github.com/justin-aj/go-hw1/logging"
+	"github.com/justin-aj/go-hw1/metrics"
 )
 
-var s3Client *s3.Client
+var backend storage.Backend
+
+// defaultConcurrency is the concurrency this worker reports when it registers
+// with a coordinator on startup. Registration uses the SELF_ADDR and
+// COORDINATOR_ADDR env vars; both are optional: if either is unset the
+// mapper still works standalone, invoked directly with pre-chosen keys.
+const defaultConcurrency = 4
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+func main() {
+	storageName := flag.String("storage", "", "storage backend: s3, local, or minio (default: $STORAGE_BACKEND or s3)")
+	flag.Parse()
+
+	var err error
+	backend, err = storage.New(*storageName, storage.DefaultConfig)
 	if err != nil {
-		log.Fatalf("unable to load SDK config: %v", err)
+		logging.Logger.Fatal().Err(err).Msg("unable to init storage backend")
 	}
-	s3Client = s3.NewFromConfig(cfg)
-}
 
-func main() {
 	r := gin.Default()
+	r.Use(logging.Middleware(), metrics.Middleware())
 
 	r.GET("/map", mapHandler)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	registerWithCoordinator()
 
 	r.Run(":8080")
 }
 
+// registerWithCoordinator POSTs this worker's address to COORDINATOR_ADDR,
+// if set, so the coordinator can dispatch map tasks here.
+func registerWithCoordinator() {
+	coordAddr := os.Getenv("COORDINATOR_ADDR")
+	selfAddr := os.Getenv("SELF_ADDR")
+	if coordAddr == "" || selfAddr == "" {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"addr":        selfAddr,
+		"concurrency": defaultConcurrency,
+	})
+	if err != nil {
+		log.Printf("mapper: failed to build registration payload: %v", err)
+		return
+	}
+
+	resp, err := http.Post(coordAddr+"/register", "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("mapper: failed to register with coordinator at %s: %v", coordAddr, err)
+		return
+	}
+	resp.Body.Close()
+}
+
 func mapHandler(c *gin.Context) {
 	bucket := c.Query("bucket")
 	key := c.Query("key")
 	outputKey := c.Query("output_key")
+	taskID := c.Query("task_id") // set by the coordinator; empty for standalone invocations
 
 	if bucket == "" || key == "" || outputKey == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket, key, and output_key query params required"})
 		return
 	}
 
-	// 1. Download chunk from S3
-	result, err := s3Client.GetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
+	taskStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("map").Observe(time.Since(taskStart).Seconds())
+	}()
+
+	// 1. Download chunk from the configured storage backend
+	reader, err := backend.Get(c.Request.Context(), bucket+"/"+key)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get S3 object: %v", err)})
+		metrics.ErrorsTotal.WithLabelValues("storage_get").Inc()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get object: %v", err)})
 		return
 	}
-	defer result.Body.Close()
+	defer reader.Close()
 
-	body, err := io.ReadAll(result.Body)
+	body, err := io.ReadAll(reader)
 	if err != nil {
+		metrics.ErrorsTotal.WithLabelValues("storage_read").Inc()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read body: %v", err)})
 		return
 	}
 
 	text := string(body)
-
-	// 2. Count word occurrences
-	wordCounts := make(map[string]int)
 	words := strings.Fields(text)
-	for _, word := range words {
-		cleaned := strings.ToLower(strings.Trim(word, ".,!?;:\"'()[]{}"))
-		if cleaned != "" {
-			wordCounts[cleaned]++
-		}
-	}
 
-	// 3. Convert to JSON and upload to S3
-	jsonData, err := json.Marshal(wordCounts)
+	// 2. Combine: tokenize, drop stop words, spill sorted runs once the
+	// in-memory map grows too large, then k-way merge the runs.
+	cfg := parseCombinerConfig(c.Query("topk"), c.Query("stopwords"), c.Query("spill_threshold"), c.Query("partitions"))
+	merged, err := combine(words, cfg)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to marshal JSON: %v", err)})
+		metrics.ErrorsTotal.WithLabelValues("combine").Inc()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to combine word counts: %v", err)})
 		return
 	}
 
-	_, err = s3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(outputKey),
-		Body:        strings.NewReader(string(jsonData)),
-		ContentType: aws.String("application/json"),
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to upload results: %v", err)})
-		return
+	// 3. Partition the sorted output so reducers can each stream-merge just
+	// their own partition, and upload one file per partition.
+	partitions := make([][]wordCount, cfg.partitions)
+	for _, wc := range merged {
+		p := partitionOf(wc.word, cfg.partitions)
+		partitions[p] = append(partitions[p], wc)
+	}
+
+	for p, entries := range partitions {
+		var buf bytes.Buffer
+		for _, wc := range entries {
+			fmt.Fprintf(&buf, "%s\t%d\n", wc.word, wc.count)
+		}
+		partitionKey := bucket + "/" + partitionOutputKey(outputKey, p)
+		if err := backend.Put(c.Request.Context(), partitionKey, &buf, "text/plain"); err != nil {
+			metrics.ErrorsTotal.WithLabelValues("storage_put").Inc()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to upload partition %d: %v", p, err)})
+			return
+		}
 	}
 
-	// 4. Return output URL
+	// 4. Return output location
+	logging.FromContext(c).Info().
+		Str("task_id", taskID).
+		Str("bucket", bucket).
+		Int("unique_words", len(merged)).
+		Int("total_words", len(words)).
+		Msg("map task complete")
+
 	c.JSON(http.StatusOK, gin.H{
+		"task_id":      taskID,
 		"message":      "map complete",
-		"output":       fmt.Sprintf("s3://%s/%s", bucket, outputKey),
-		"unique_words": len(wordCounts),
+		"output":       fmt.Sprintf("%s/%s", bucket, outputKey),
+		"partitions":   cfg.partitions,
+		"unique_words": len(merged),
 		"total_words":  len(words),
 	})
 }
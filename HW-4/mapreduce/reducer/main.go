@@ -1,44 +1,83 @@
 package main
 
 import (
-	"context"
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/justin-aj/go-hw1/HW-4/mapreduce/storage"
+	"github.com/justin-aj/go-hw1/logging"
+	"github.com/justin-aj/go-hw1/metrics"
 )
 
-var s3Client *s3.Client
+var backend storage.Backend
+
+const defaultConcurrency = 4
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+func main() {
+	storageName := flag.String("storage", "", "storage backend: s3, local, or minio (default: $STORAGE_BACKEND or s3)")
+	flag.Parse()
+
+	var err error
+	backend, err = storage.New(*storageName, storage.DefaultConfig)
 	if err != nil {
-		log.Fatalf("unable to load SDK config: %v", err)
+		logging.Logger.Fatal().Err(err).Msg("unable to init storage backend")
 	}
-	s3Client = s3.NewFromConfig(cfg)
-}
 
-func main() {
 	r := gin.Default()
+	r.Use(logging.Middleware(), metrics.Middleware())
 
 	r.GET("/reduce", reduceHandler)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	registerWithCoordinator()
 
 	r.Run(":8080")
 }
 
+// registerWithCoordinator POSTs this worker's address to COORDINATOR_ADDR,
+// if set, so the coordinator can dispatch reduce tasks here.
+func registerWithCoordinator() {
+	coordAddr := os.Getenv("COORDINATOR_ADDR")
+	selfAddr := os.Getenv("SELF_ADDR")
+	if coordAddr == "" || selfAddr == "" {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"addr":        selfAddr,
+		"concurrency": defaultConcurrency,
+	})
+	if err != nil {
+		log.Printf("reducer: failed to build registration payload: %v", err)
+		return
+	}
+
+	resp, err := http.Post(coordAddr+"/register", "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("reducer: failed to register with coordinator at %s: %v", coordAddr, err)
+		return
+	}
+	resp.Body.Close()
+}
+
 func reduceHandler(c *gin.Context) {
 	bucket := c.Query("bucket")
 	keysParam := c.Query("keys")
+	taskID := c.Query("task_id") // set by the coordinator; empty for standalone invocations
 
 	if bucket == "" || keysParam == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket and keys query params required"})
@@ -47,63 +86,64 @@ func reduceHandler(c *gin.Context) {
 
 	keys := strings.Split(keysParam, ",")
 
-	// 1. Download and aggregate all mapper outputs
-	finalCounts := make(map[string]int)
-
-	for _, key := range keys {
-		key = strings.TrimSpace(key)
-
-		result, err := s3Client.GetObject(context.TODO(), &s3.GetObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get %s: %v", key, err)})
-			return
-		}
-
-		body, err := io.ReadAll(result.Body)
-		result.Body.Close()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read %s: %v", key, err)})
-			return
-		}
-
-		var wordCounts map[string]int
-		if err := json.Unmarshal(body, &wordCounts); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse %s: %v", key, err)})
+	taskStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("reduce").Observe(time.Since(taskStart).Seconds())
+	}()
+
+	// 1. Stream-merge the mapper outputs for this partition. Mapper output is
+	// always written per-partition as sorted "word\tcount" runs (partitions
+	// defaults to 1, i.e. a single ".part-000" file per mapper), so the
+	// reducer can k-way merge with container/heap instead of loading each
+	// mapper's full output into memory. Partition defaults to 0 to match
+	// that single-partition default.
+	partition := 0
+	if partitionParam := c.Query("partition"); partitionParam != "" {
+		var perr error
+		partition, perr = strconv.Atoi(partitionParam)
+		if perr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "partition must be an integer"})
 			return
 		}
+	}
 
-		// Aggregate counts
-		for word, count := range wordCounts {
-			finalCounts[word] += count
-		}
+	finalCounts, err := streamMergePartition(c.Request.Context(), backend, bucket, keys, partition)
+	if err != nil {
+		metrics.ErrorsTotal.WithLabelValues("stream_merge").Inc()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// 2. Save final result to S3
-	outputKey := "results/final_counts.json"
+	// 2. Save final result via the storage backend. If we're talking to S3,
+	// upload through the transfer manager (supports outputs over the 5GB
+	// single-PutObject limit) with any requested storage class / SSE. The
+	// partition must be part of the key: one reducer runs per partition, and
+	// without it every partition would overwrite the same object.
+	outputKey := fmt.Sprintf("results/final_counts.part-%03d.json", partition)
 	jsonData, err := json.Marshal(finalCounts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to marshal final JSON: %v", err)})
 		return
 	}
 
-	_, err = s3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(outputKey),
-		Body:        strings.NewReader(string(jsonData)),
-		ContentType: aws.String("application/json"),
-	})
-	if err != nil {
+	if err := putFinalResult(c, bucket+"/"+outputKey, jsonData); err != nil {
+		metrics.ErrorsTotal.WithLabelValues("storage_put").Inc()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to upload final results: %v", err)})
 		return
 	}
 
-	// 3. Return final output URL
+	logging.FromContext(c).Info().
+		Str("task_id", taskID).
+		Str("bucket", bucket).
+		Int("unique_words", len(finalCounts)).
+		Int("mappers_processed", len(keys)).
+		Msg("reduce task complete")
+
+	// 3. Return final output location
 	c.JSON(http.StatusOK, gin.H{
+		"task_id":           taskID,
 		"message":           "reduce complete",
-		"output":            fmt.Sprintf("s3://%s/%s", bucket, outputKey),
+		"output":            fmt.Sprintf("%s/%s", bucket, outputKey),
 		"unique_words":      len(finalCounts),
 		"mappers_processed": len(keys),
 	})
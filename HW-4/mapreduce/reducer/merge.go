@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/justin-aj/go-hw1/HW-4/mapreduce/storage"
+)
+
+// mapperCursor streams one mapper's sorted partition output line by line, so
+// the reducer never has to hold a full mapper output in memory.
+type mapperCursor struct {
+	reader io.ReadCloser
+	lines  *bufio.Scanner
+	word   string
+	count  int
+}
+
+func (mc *mapperCursor) advance() bool {
+	if !mc.lines.Scan() {
+		return false
+	}
+	idx := strings.LastIndexByte(mc.lines.Text(), '\t')
+	if idx < 0 {
+		return mc.advance()
+	}
+	mc.word = mc.lines.Text()[:idx]
+	mc.count, _ = strconv.Atoi(mc.lines.Text()[idx+1:])
+	return true
+}
+
+type cursorHeap []*mapperCursor
+
+func (h cursorHeap) Len() int            { return len(h) }
+func (h cursorHeap) Less(i, j int) bool  { return h[i].word < h[j].word }
+func (h cursorHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeap) Push(x interface{}) { *h = append(*h, x.(*mapperCursor)) }
+func (h *cursorHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// streamMergePartition opens partition p of every mapper output key, then
+// k-way merges the already-sorted streams with container/heap, summing
+// counts for words that appear in more than one mapper's output. The heap
+// holds one line per mapper; the returned map holds every distinct word of
+// the partition.
+func streamMergePartition(ctx context.Context, backend storage.Backend, bucket string, mapperKeys []string, partition int) (map[string]int, error) {
+	h := &cursorHeap{}
+	defer func() {
+		for _, mc := range *h {
+			mc.reader.Close()
+		}
+	}()
+
+	for _, baseKey := range mapperKeys {
+		baseKey = strings.TrimSpace(baseKey)
+		if baseKey == "" {
+			continue
+		}
+		key := partitionOutputKey(baseKey, partition)
+		reader, err := backend.Get(ctx, bucket+"/"+key)
+		if err != nil {
+			return nil, err
+		}
+		mc := &mapperCursor{reader: reader, lines: bufio.NewScanner(reader)}
+		if mc.advance() {
+			heap.Push(h, mc)
+		} else {
+			reader.Close()
+		}
+	}
+
+	finalCounts := make(map[string]int)
+	for h.Len() > 0 {
+		top := (*h)[0]
+		word, count := top.word, top.count
+
+		for h.Len() > 0 && (*h)[0].word == word {
+			mc := heap.Pop(h).(*mapperCursor)
+			if mc != top {
+				count += mc.count
+			}
+			if mc.advance() {
+				heap.Push(h, mc)
+			} else {
+				mc.reader.Close()
+			}
+		}
+
+		finalCounts[word] = count
+	}
+
+	return finalCounts, nil
+}
+
+// partitionOutputKey mirrors the mapper's naming so the reducer can find
+// each mapper's per-partition file.
+func partitionOutputKey(outputKey string, p int) string {
+	return fmt.Sprintf("%s.part-%03d", outputKey, p)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gin-gonic/gin"
+
+	"github.com/justin-aj/go-hw1/HW-4/mapreduce/storage"
+)
+
+// putFinalResult uploads the reducer's final JSON output. When the
+// configured backend is S3, it goes through PutWithOptions so large outputs
+// use multipart upload and callers can request a storage class or
+// server-side encryption via query params; other backends fall back to the
+// plain Backend.Put.
+func putFinalResult(c *gin.Context, key string, data []byte) error {
+	s3Backend, ok := backend.(*storage.S3Backend)
+	if !ok {
+		return backend.Put(c.Request.Context(), key, bytes.NewReader(data), "application/json")
+	}
+
+	opts := storage.PutOptions{
+		StorageClass: types.StorageClass(c.Query("storage_class")),
+		SSEAlgorithm: types.ServerSideEncryption(c.Query("sse")),
+		SSEKMSKeyID:  c.Query("sse_kms_key_id"),
+	}
+	return s3Backend.PutWithOptions(c.Request.Context(), key, bytes.NewReader(data), "application/json", opts)
+}
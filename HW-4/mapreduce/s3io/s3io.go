@@ -0,0 +1,113 @@
+// Package s3io provides the S3 I/O helpers the reducer needs for outputs
+// too large to upload in a single PutObject call: a multipart-upload wrapper
+// around the S3 transfer manager and a context-aware retry loop with
+// exponential backoff and jitter.
+package s3io
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// RetryPolicy controls how Retry backs off between attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used by callers that don't need a custom policy.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseBackoff: 200 * time.Millisecond}
+
+// Retry runs fn until it succeeds, ctx is cancelled, or the policy's
+// MaxAttempts is exhausted, backing off exponentially with jitter between
+// attempts. Unlike a plain loop with time.Sleep, it honors ctx cancellation
+// immediately instead of sleeping out a stale attempt.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultRetryPolicy.MaxAttempts
+	}
+	backoff := policy.BaseBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryPolicy.BaseBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(backoff) + 1))
+		select {
+		case <-time.After(backoff + jitter):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// UploadOptions configures the multipart upload used by Upload.
+type UploadOptions struct {
+	PartSize     int64  // bytes per part; manager.DefaultUploadPartSize (5MB) if zero
+	Concurrency  int    // parallel part uploads; manager.DefaultUploadConcurrency (5) if zero
+	StorageClass types.StorageClass
+	SSEAlgorithm types.ServerSideEncryption
+	SSEKMSKeyID  string
+}
+
+// Upload writes body to bucket/key using the S3 transfer manager, so
+// outputs larger than the 5GB single-PutObject limit still work, and
+// retries the whole upload with backoff+jitter on transient failure. body
+// must be an io.ReadSeeker so a failed attempt can be retried from the
+// start.
+func Upload(ctx context.Context, client *s3.Client, bucket, key string, body io.ReadSeeker, contentType string, opts UploadOptions, policy RetryPolicy) error {
+	uploader := manager.NewUploader(client, func(u *manager.Uploader) {
+		if opts.PartSize > 0 {
+			u.PartSize = opts.PartSize
+		}
+		if opts.Concurrency > 0 {
+			u.Concurrency = opts.Concurrency
+		}
+	})
+
+	return Retry(ctx, policy, func(ctx context.Context) error {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		input := &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(key),
+			Body:        body,
+			ContentType: aws.String(contentType),
+		}
+		if opts.StorageClass != "" {
+			input.StorageClass = opts.StorageClass
+		}
+		if opts.SSEAlgorithm != "" {
+			input.ServerSideEncryption = opts.SSEAlgorithm
+			if opts.SSEKMSKeyID != "" {
+				input.SSEKMSKeyId = aws.String(opts.SSEKMSKeyID)
+			}
+		}
+
+		_, err := uploader.Upload(ctx, input)
+		return err
+	})
+}
@@ -0,0 +1,59 @@
+package s3io
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetrySucceedsAfterTransientFailures(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	attempts := 0
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry() error = %v, want nil", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+	attempts := 0
+	wantErr := errors.New("persistent")
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("Retry() error = %v, want %v", err, wantErr)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestRetryStopsImmediatelyOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := Retry(ctx, DefaultRetryPolicy, func(ctx context.Context) error {
+		attempts++
+		return errors.New("should not run")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Retry() error = %v, want context.Canceled", err)
+	}
+	if attempts != 0 {
+		t.Fatalf("attempts = %d, want 0 since ctx was already cancelled", attempts)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalBackend stores objects as files under a root directory, keyed the
+// same way as the S3 backend ("bucket/key" maps to root/bucket/key). It
+// exists so the mapper and reducer can be exercised without AWS
+// credentials, e.g. in unit tests or local development.
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend builds a Backend rooted at dir, creating it if needed.
+func NewLocalBackend(dir string) (*LocalBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBackend{root: dir}, nil
+}
+
+// path joins key onto root, rejecting any key that would resolve outside of
+// it (e.g. via "..") so callers can't read or write arbitrary files on the
+// host. bucket/key come straight from request query params, so this is a
+// trust boundary, not a defensive nicety.
+func (b *LocalBackend) path(key string) (string, error) {
+	root, err := filepath.Abs(b.root)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(root, filepath.FromSlash(key))
+	if dest != root && !strings.HasPrefix(dest, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("key %q escapes storage root", key)
+	}
+	return dest, nil
+}
+
+func (b *LocalBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	dest, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(dest)
+}
+
+func (b *LocalBackend) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	dest, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, body)
+	return err
+}
+
+func (b *LocalBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	base, err := b.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(base)
+
+	var keys []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, prefix) {
+			keys = append(keys, rel)
+		}
+		return nil
+	})
+	return keys, err
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestLocalBackendRejectsPathTraversal(t *testing.T) {
+	b, err := NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalBackend() error = %v", err)
+	}
+
+	ctx := context.Background()
+	traversalKeys := []string{
+		"../outside.txt",
+		"../../etc/passwd",
+		"nested/../../escape.txt",
+	}
+	for _, key := range traversalKeys {
+		if _, err := b.Get(ctx, key); err == nil {
+			t.Errorf("Get(%q) succeeded, want error (path escapes root)", key)
+		}
+		if err := b.Put(ctx, key, bytes.NewReader([]byte("x")), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded, want error (path escapes root)", key)
+		}
+	}
+}
+
+func TestLocalBackendRoundTripsWithinRoot(t *testing.T) {
+	b, err := NewLocalBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalBackend() error = %v", err)
+	}
+
+	ctx := context.Background()
+	want := []byte("hello world")
+	if err := b.Put(ctx, "bucket/nested/key.txt", bytes.NewReader(want), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	r, err := b.Get(ctx, "bucket/nested/key.txt")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer r.Close()
+
+	got := make([]byte, len(want))
+	if _, err := r.Read(got); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Get() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MinioBackend talks to a MinIO (or other S3-compatible) endpoint using the
+// AWS SDK with a custom endpoint and path-style addressing, so the mapper
+// and reducer can be tested against a self-hosted object store instead of
+// real S3.
+type MinioBackend struct {
+	*S3Backend
+}
+
+// NewMinioBackend builds a Backend from the MINIO_* env vars:
+// MINIO_ENDPOINT (required), MINIO_ACCESS_KEY, MINIO_SECRET_KEY, and
+// MINIO_INSECURE ("true" to skip TLS verification, for local testing).
+func NewMinioBackend(cfg Config) (*MinioBackend, error) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("MINIO_ENDPOINT must be set to use the minio backend")
+	}
+	accessKey := os.Getenv("MINIO_ACCESS_KEY")
+	secretKey := os.Getenv("MINIO_SECRET_KEY")
+	insecure, _ := strconv.ParseBool(os.Getenv("MINIO_INSECURE"))
+
+	httpClient := newHTTPClient(cfg)
+	if insecure {
+		httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+		config.WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config for minio: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+		if cfg.Signer != nil {
+			o.HTTPSignerV4 = cfg.Signer
+		}
+	})
+
+	return &MinioBackend{S3Backend: &S3Backend{client: client, cfg: cfg}}, nil
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssigner "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/justin-aj/go-hw1/HW-4/mapreduce/s3io"
+	"github.com/justin-aj/go-hw1/metrics"
+)
+
+// S3Backend talks to AWS S3 (or any endpoint the SDK is pointed at).
+type S3Backend struct {
+	client *s3.Client
+	cfg    Config
+}
+
+// newHTTPClient builds the http.Client the S3 client issues requests with,
+// applying cfg's connect/read timeouts (the SDK's default config has no
+// connect timeout and an effectively unbounded read timeout).
+func newHTTPClient(cfg Config) *http.Client {
+	connectTimeout := cfg.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = DefaultConfig.ConnectTimeout
+	}
+	readTimeout := cfg.ReadTimeout
+	if readTimeout <= 0 {
+		readTimeout = DefaultConfig.ReadTimeout
+	}
+	return &http.Client{
+		Timeout: readTimeout,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: connectTimeout}).DialContext,
+		},
+	}
+}
+
+// NewS3Backend builds a Backend backed by the default AWS S3 client.
+func NewS3Backend(cfg Config) (*S3Backend, error) {
+	awsCfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion("us-east-1"),
+		config.WithHTTPClient(newHTTPClient(cfg)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	return &S3Backend{client: s3.NewFromConfig(awsCfg), cfg: cfg}, nil
+}
+
+// NewS3BackendWithSigner builds a Backend using a custom AWS v4 signer, for
+// regions or endpoints that need signing overrides.
+func NewS3BackendWithSigner(cfg Config, signer awssigner.HTTPSigner) (*S3Backend, error) {
+	awsCfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion("us-east-1"),
+		config.WithHTTPClient(newHTTPClient(cfg)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.HTTPSignerV4 = signer
+	})
+	return &S3Backend{client: client, cfg: cfg}, nil
+}
+
+func (b *S3Backend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	defer observeDuration("get")()
+
+	bucket, objectKey := splitBucketKey(key)
+	var out io.ReadCloser
+	err := withRetry(ctx, b.cfg.Retry, func(ctx context.Context) error {
+		result, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(objectKey),
+		})
+		if err != nil {
+			return err
+		}
+		if result.ContentLength != nil {
+			metrics.S3ObjectGetSize.Observe(float64(*result.ContentLength))
+			metrics.StorageBytes.WithLabelValues("s3", "read").Add(float64(*result.ContentLength))
+		}
+		out = result.Body
+		return nil
+	})
+	return out, err
+}
+
+func (b *S3Backend) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	defer observeDuration("put")()
+
+	bucket, objectKey := splitBucketKey(key)
+	uploader := manager.NewUploader(b.client)
+	return withRetry(ctx, b.cfg.Retry, func(ctx context.Context) error {
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(objectKey),
+			Body:        body,
+			ContentType: aws.String(contentType),
+		})
+		return err
+	})
+}
+
+// PutOptions configures PutWithOptions beyond the plain Backend interface:
+// the multipart part size/concurrency, storage class, and SSE headers for
+// the upload.
+type PutOptions struct {
+	PartSize     int64
+	Concurrency  int
+	StorageClass types.StorageClass
+	SSEAlgorithm types.ServerSideEncryption
+	SSEKMSKeyID  string
+}
+
+// PutWithOptions uploads body (which must support Seek so a retried attempt
+// can restart from the beginning) via the S3 transfer manager, honoring
+// StorageClass/SSE and retrying the whole upload with backoff+jitter while
+// respecting ctx cancellation. Callers that need per-request storage class
+// or encryption (the generic Backend interface has no room for them) should
+// type-assert to *S3Backend and call this instead of Put.
+func (b *S3Backend) PutWithOptions(ctx context.Context, key string, body io.ReadSeeker, contentType string, opts PutOptions) error {
+	defer observeDuration("put")()
+
+	bucket, objectKey := splitBucketKey(key)
+	return s3io.Upload(ctx, b.client, bucket, objectKey, body, contentType, s3io.UploadOptions{
+		PartSize:     opts.PartSize,
+		Concurrency:  opts.Concurrency,
+		StorageClass: opts.StorageClass,
+		SSEAlgorithm: opts.SSEAlgorithm,
+		SSEKMSKeyID:  opts.SSEKMSKeyID,
+	}, s3io.RetryPolicy{MaxAttempts: b.cfg.Retry.MaxAttempts, BaseBackoff: b.cfg.Retry.BaseBackoff})
+}
+
+func (b *S3Backend) List(ctx context.Context, prefix string) ([]string, error) {
+	defer observeDuration("list")()
+
+	bucket, objectPrefix := splitBucketKey(prefix)
+	var keys []string
+	err := withRetry(ctx, b.cfg.Retry, func(ctx context.Context) error {
+		keys = keys[:0]
+		paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(objectPrefix),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			for _, obj := range page.Contents {
+				keys = append(keys, aws.ToString(obj.Key))
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// observeDuration starts a timer for a storage operation and returns a func
+// to call (via defer) when it completes, recording StorageOpDuration.
+func observeDuration(operation string) func() {
+	start := time.Now()
+	return func() {
+		metrics.StorageOpDuration.WithLabelValues("s3", operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// splitBucketKey accepts either "bucket/key" or a bare key when the bucket
+// is implied by the caller's configuration; mapper/reducer always pass
+// "bucket/key" so this is a simple split on the first slash.
+func splitBucketKey(s string) (bucket, key string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' {
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+// withRetry wraps s3io.Retry (the same context-aware backoff+jitter loop
+// PutWithOptions uses) so Get/Put/List honor ctx cancellation during backoff
+// instead of sleeping it out, and records S3RetriedCalls when an attempt
+// after the first succeeds.
+func withRetry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	attempts := 0
+	err := s3io.Retry(ctx, s3io.RetryPolicy{MaxAttempts: policy.MaxAttempts, BaseBackoff: policy.BaseBackoff}, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	if err == nil && attempts > 1 {
+		metrics.S3RetriedCalls.Inc()
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSplitBucketKey(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantBucket string
+		wantKey    string
+	}{
+		{"bucket/key", "bucket", "key"},
+		{"bucket/nested/key.json", "bucket", "nested/key.json"},
+		{"bucket-only", "bucket-only", ""},
+	}
+	for _, c := range cases {
+		bucket, key := splitBucketKey(c.in)
+		if bucket != c.wantBucket || key != c.wantKey {
+			t.Errorf("splitBucketKey(%q) = (%q, %q), want (%q, %q)", c.in, bucket, key, c.wantBucket, c.wantKey)
+		}
+	}
+}
+
+func TestWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+	attempts := 0
+	err := withRetry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry() error = %v, want nil", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+	attempts := 0
+	wantErr := errors.New("persistent")
+	err := withRetry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("withRetry() error = %v, want %v", err, wantErr)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestWithRetryStopsImmediatelyOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := withRetry(ctx, DefaultRetryPolicy, func(ctx context.Context) error {
+		attempts++
+		return errors.New("should not run")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("withRetry() error = %v, want context.Canceled", err)
+	}
+	if attempts != 0 {
+		t.Fatalf("attempts = %d, want 0 since ctx was already cancelled", attempts)
+	}
+}
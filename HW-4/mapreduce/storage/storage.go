@@ -0,0 +1,79 @@
+// Package storage abstracts the object store used by the mapper and reducer
+// so they can run against S3, a local filesystem, or a MinIO (or other
+// S3-compatible) endpoint without code changes.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	awssigner "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Backend is the object-store operations the mapper and reducer need.
+type Backend interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// RetryPolicy controls how backends retry transient failures.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the Retry policy of DefaultConfig. A Config whose
+// Retry fields are zero falls back to s3io.DefaultRetryPolicy instead.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseBackoff: 200 * time.Millisecond}
+
+// Config holds the settings common to every backend implementation.
+type Config struct {
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	Retry          RetryPolicy
+
+	// Signer overrides the S3 client's AWS v4 request signer, for endpoints
+	// that need non-default signing (e.g. unsigned payloads). Only consulted
+	// by the s3 and minio backends; nil uses the SDK's default signer.
+	Signer awssigner.HTTPSigner
+}
+
+// DefaultConfig is used when a backend is constructed without an explicit Config.
+var DefaultConfig = Config{
+	ConnectTimeout: 5 * time.Second,
+	ReadTimeout:    30 * time.Second,
+	Retry:          DefaultRetryPolicy,
+}
+
+// New selects and constructs a Backend by name ("s3", "local", or "minio"),
+// falling back to the STORAGE_BACKEND env var and then "s3" if name is empty.
+func New(name string, cfg Config) (Backend, error) {
+	if name == "" {
+		name = os.Getenv("STORAGE_BACKEND")
+	}
+	if name == "" {
+		name = "s3"
+	}
+
+	switch name {
+	case "s3":
+		if cfg.Signer != nil {
+			return NewS3BackendWithSigner(cfg, cfg.Signer)
+		}
+		return NewS3Backend(cfg)
+	case "local":
+		root := os.Getenv("LOCAL_STORAGE_ROOT")
+		if root == "" {
+			root = "./data"
+		}
+		return NewLocalBackend(root)
+	case "minio":
+		return NewMinioBackend(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", name)
+	}
+}
@@ -0,0 +1,56 @@
+// Package logging provides request-scoped structured logging shared by the
+// albums, mapper, and reducer Gin services, with a correlation ID
+// propagated from (or generated for) each request.
+package logging
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Logger is the process-wide structured logger; individual requests attach
+// a correlation ID via With().Logger() rather than mutating this one.
+var Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+const correlationIDHeader = "X-Request-ID"
+const contextKey = "logger"
+
+// Middleware assigns (or propagates) a correlation ID, stores a logger
+// carrying it in the Gin context under contextKey, and logs the outcome of
+// every request.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader(correlationIDHeader)
+		if correlationID == "" {
+			correlationID = uuid.NewString()
+		}
+		c.Writer.Header().Set(correlationIDHeader, correlationID)
+
+		reqLogger := Logger.With().Str("correlation_id", correlationID).Logger()
+		c.Set(contextKey, &reqLogger)
+
+		c.Next()
+
+		reqLogger.Info().
+			Str("method", c.Request.Method).
+			Str("path", c.FullPath()).
+			Int("status", c.Writer.Status()).
+			Msg("request handled")
+	}
+}
+
+// FromContext returns the request-scoped logger attached by Middleware,
+// falling back to the package logger if none is set (e.g. outside a request).
+// It returns a pointer since zerolog.Logger's Info/Debug/etc. methods have
+// pointer receivers.
+func FromContext(c *gin.Context) *zerolog.Logger {
+	if l, ok := c.Get(contextKey); ok {
+		if logger, ok := l.(*zerolog.Logger); ok {
+			return logger
+		}
+	}
+	return &Logger
+}
@@ -0,0 +1,87 @@
+// Package metrics defines the Prometheus collectors shared by the albums,
+// mapper, and reducer Gin services, and a middleware that records request
+// count and duration per route.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// RequestsTotal counts every HTTP request handled, labeled by route,
+	// method, and response status.
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests handled.",
+	}, []string{"route", "method", "status"})
+
+	// RequestDuration tracks request latency per route and method.
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// StorageOpDuration tracks storage.Backend operation latency.
+	StorageOpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "storage_operation_duration_seconds",
+		Help:    "storage.Backend operation latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend", "operation"})
+
+	// StorageBytes counts bytes moved through storage.Backend, by direction.
+	StorageBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "storage_bytes_total",
+		Help: "Bytes read from or written to the storage backend.",
+	}, []string{"backend", "direction"})
+
+	// S3ObjectGetSize histograms the size of objects fetched via GetObject,
+	// one observation per fetched key. It carries no labels, so individual
+	// keys are not distinguished.
+	S3ObjectGetSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "s3_get_object_size_bytes",
+		Help:    "Size in bytes of objects fetched via S3 GetObject.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 10),
+	})
+
+	// S3RetriedCalls counts S3 calls that needed at least one retry.
+	S3RetriedCalls = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "s3_retried_calls_total",
+		Help: "Total number of S3 calls that were retried at least once.",
+	})
+
+	// TaskDuration tracks map/reduce task duration, labeled by task type.
+	TaskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mapreduce_task_duration_seconds",
+		Help:    "Map/reduce task duration in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"task_type"})
+
+	// ErrorsTotal counts handled errors by a short type label (e.g. "storage_get", "storage_put").
+	ErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "errors_total",
+		Help: "Total number of errors, labeled by error type.",
+	}, []string{"type"})
+)
+
+// Middleware records RequestsTotal and RequestDuration for every request
+// that passes through it. Wire it in ahead of the route handlers.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		RequestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+		RequestsTotal.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}